@@ -0,0 +1,111 @@
+// Command iodatafmt walks a directory and rewrites serialized data files
+// from one format to another, mirroring the toyaml/tojson/totoml helpers
+// of Hugo's front matter converter.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	iodatafmt "github.com/novaez/iodatafmt"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: iodatafmt [-force] <toyaml|tojson|totoml> <dir>")
+	os.Exit(2)
+}
+
+func main() {
+	force := flag.Bool("force", false, "overwrite destination files that already exist")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		usage()
+	}
+
+	var to iodatafmt.DataFmt
+	switch flag.Arg(0) {
+	case "toyaml":
+		to = iodatafmt.YAML
+	case "tojson":
+		to = iodatafmt.JSON
+	case "totoml":
+		to = iodatafmt.TOML
+	default:
+		usage()
+	}
+
+	if err := convertDir(flag.Arg(1), to, *force); err != nil {
+		fmt.Fprintln(os.Stderr, "iodatafmt:", err)
+		os.Exit(1)
+	}
+}
+
+// convertDir rewrites every recognized data file under dir into format to,
+// renaming the file extension to match and removing the original. Unless
+// force is set, a file is skipped - original left in place, nothing written
+// - when its destination path already exists, so converting a directory
+// that has both config.json and config.yaml can't let one silently clobber
+// the other. A per-file conversion error is logged and the walk continues,
+// so one malformed file doesn't abort the whole directory with some files
+// already converted and others not - convertDir reports how many files it
+// skipped or failed once the walk finishes.
+func convertDir(dir string, to iodatafmt.DataFmt, force bool) error {
+	var skipped, failed int
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		from, err := iodatafmt.FileFormat(path)
+		if err != nil || from == to {
+			return nil
+		}
+
+		dst := strings.TrimSuffix(path, filepath.Ext(path)) + extFor(to)
+		if !force {
+			if _, err := os.Stat(dst); err == nil {
+				fmt.Fprintf(os.Stderr, "iodatafmt: skipping %s: %s already exists (use -force to overwrite)\n", path, dst)
+				skipped++
+				return nil
+			}
+		}
+
+		if err := iodatafmt.ConvertFile(path, dst); err != nil {
+			fmt.Fprintf(os.Stderr, "iodatafmt: %s: %v\n", path, err)
+			failed++
+			return nil
+		}
+
+		return os.Remove(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 || failed > 0 {
+		return fmt.Errorf("%s: %d file(s) skipped, %d file(s) failed to convert", dir, skipped, failed)
+	}
+	return nil
+}
+
+func extFor(f iodatafmt.DataFmt) string {
+	switch f {
+	case iodatafmt.YAML:
+		return ".yaml"
+	case iodatafmt.JSON:
+		return ".json"
+	case iodatafmt.TOML:
+		return ".toml"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	iodatafmt "github.com/novaez/iodatafmt"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+}
+
+func TestConvertDirSkipsExistingDestination(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.json"), `{"a": 1}`)
+	writeFile(t, filepath.Join(dir, "config.yaml"), "b: 2\n")
+
+	if err := convertDir(dir, iodatafmt.YAML, false); err == nil {
+		t.Fatal("expected an error reporting the skipped conversion")
+	}
+
+	// Neither file should have been touched: config.json must still exist
+	// (not removed) and config.yaml must still hold its original content.
+	if _, err := os.Stat(filepath.Join(dir, "config.json")); err != nil {
+		t.Errorf("config.json should still exist: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile(config.yaml): %v", err)
+	}
+	if string(b) != "b: 2\n" {
+		t.Errorf("config.yaml was overwritten, got %q", b)
+	}
+}
+
+func TestConvertDirContinuesPastBadFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bad.json"), `not valid json`)
+	writeFile(t, filepath.Join(dir, "good.json"), `{"a": 1}`)
+
+	if err := convertDir(dir, iodatafmt.YAML, false); err == nil {
+		t.Fatal("expected an error summarizing the failed conversion")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "good.yaml")); err != nil {
+		t.Errorf("good.json should have converted despite bad.json failing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.json")); err != nil {
+		t.Errorf("bad.json should be left in place after a failed conversion: %v", err)
+	}
+}
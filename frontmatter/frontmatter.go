@@ -0,0 +1,116 @@
+// Package frontmatter splits documents that begin with a YAML (`---`),
+// TOML (`+++`) or JSON (`{`) front matter block from the body that
+// follows, reusing iodatafmt's Marshal/Unmarshal for the decoded block.
+package frontmatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	iodatafmt "github.com/novaez/iodatafmt"
+)
+
+var (
+	yamlDelim = []byte("---\n")
+	tomlDelim = []byte("+++\n")
+)
+
+// ParseFrontmatter splits b into its decoded front matter and the body
+// bytes that follow, along with the DataFmt the front matter was written
+// in.
+func ParseFrontmatter(b []byte) (interface{}, []byte, iodatafmt.DataFmt, error) {
+	switch {
+	case bytes.HasPrefix(b, yamlDelim):
+		return splitDelimited(b, yamlDelim, iodatafmt.YAML)
+	case bytes.HasPrefix(b, tomlDelim):
+		return splitDelimited(b, tomlDelim, iodatafmt.TOML)
+	case bytes.HasPrefix(bytes.TrimLeft(b, " \t\r\n"), []byte("{")):
+		return splitJSON(b)
+	default:
+		return nil, b, iodatafmt.UNKNOWN, errors.New("no front matter found")
+	}
+}
+
+// splitDelimited looks for the closing delimiter as a standalone line -
+// i.e. a line that, ignoring a trailing "\r", is exactly delim with its
+// "\n" stripped - rather than searching for the delimiter bytes anywhere in
+// the remaining content. A front matter value whose own text happens to end
+// in the same bytes (e.g. a YAML string ending in "---" right before a
+// newline) must not be mistaken for the close of the block.
+func splitDelimited(b, delim []byte, f iodatafmt.DataFmt) (interface{}, []byte, iodatafmt.DataFmt, error) {
+	marker := bytes.TrimSuffix(delim, []byte("\n"))
+	lines := bytes.Split(b[len(delim):], []byte("\n"))
+
+	end := -1
+	for i, line := range lines {
+		if bytes.Equal(bytes.TrimRight(line, "\r"), marker) {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, nil, f, errors.New("unterminated front matter")
+	}
+
+	meta, err := iodatafmt.Unmarshal(bytes.Join(lines[:end], []byte("\n")), f)
+	if err != nil {
+		return nil, nil, f, err
+	}
+
+	body := bytes.Join(lines[end+1:], []byte("\n"))
+	return meta, body, f, nil
+}
+
+func splitJSON(b []byte) (interface{}, []byte, iodatafmt.DataFmt, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	var meta interface{}
+	if err := dec.Decode(&meta); err != nil {
+		return nil, nil, iodatafmt.JSON, err
+	}
+
+	body := bytes.TrimPrefix(b[dec.InputOffset():], []byte("\n"))
+	return meta, body, iodatafmt.JSON, nil
+}
+
+// WriteFrontmatter writes meta to w, delimited for format f, followed by
+// body.
+func WriteFrontmatter(w io.Writer, meta interface{}, body []byte, f iodatafmt.DataFmt) error {
+	switch f {
+	case iodatafmt.YAML:
+		return writeDelimited(w, meta, body, yamlDelim, f)
+	case iodatafmt.TOML:
+		return writeDelimited(w, meta, body, tomlDelim, f)
+	case iodatafmt.JSON:
+		b, err := iodatafmt.Marshal(meta, f)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("\n")); err != nil {
+			return err
+		}
+		_, err = w.Write(body)
+		return err
+	default:
+		return errors.New("unsupported front matter format")
+	}
+}
+
+func writeDelimited(w io.Writer, meta interface{}, body, delim []byte, f iodatafmt.DataFmt) error {
+	b, err := iodatafmt.Marshal(meta, f)
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range [][]byte{delim, b, delim, body} {
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
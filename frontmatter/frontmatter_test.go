@@ -0,0 +1,53 @@
+package frontmatter
+
+import (
+	"bytes"
+	"testing"
+
+	iodatafmt "github.com/novaez/iodatafmt"
+)
+
+func TestParseFrontmatterIgnoresDelimiterSuffixInValue(t *testing.T) {
+	doc := []byte("---\ntitle: Section---\n---\nbody text\n")
+
+	meta, body, f, err := ParseFrontmatter(doc)
+	if err != nil {
+		t.Fatalf("ParseFrontmatter: %v", err)
+	}
+	if f != iodatafmt.YAML {
+		t.Fatalf("format = %v, want YAML", f)
+	}
+
+	m, ok := meta.(map[string]interface{})
+	if !ok || m["title"] != "Section---" {
+		t.Errorf("meta = %#v, want title: \"Section---\"", meta)
+	}
+	if string(body) != "body text\n" {
+		t.Errorf("body = %q, want %q", body, "body text\n")
+	}
+}
+
+func TestWriteParseFrontmatterRoundTrip(t *testing.T) {
+	meta := map[string]interface{}{"title": "hello"}
+	body := []byte("the body\n")
+
+	var buf bytes.Buffer
+	if err := WriteFrontmatter(&buf, meta, body, iodatafmt.YAML); err != nil {
+		t.Fatalf("WriteFrontmatter: %v", err)
+	}
+
+	gotMeta, gotBody, f, err := ParseFrontmatter(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseFrontmatter: %v", err)
+	}
+	if f != iodatafmt.YAML {
+		t.Errorf("format = %v, want YAML", f)
+	}
+	m, ok := gotMeta.(map[string]interface{})
+	if !ok || m["title"] != "hello" {
+		t.Errorf("meta = %#v, want title: hello", gotMeta)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %q, want %q", gotBody, body)
+	}
+}
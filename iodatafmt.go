@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
     "sort"
     "strconv"
 	"strings"
@@ -18,9 +20,11 @@ import (
 
 	// Third party packages.
 	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl"
 )
 
-// DataFmt represents which data serialization is used YAML, JSON or TOML.
+// DataFmt represents which data serialization is used YAML, JSON, TOML,
+// HCL or dotenv.
 type DataFmt int
 
 // Constants for data format.
@@ -28,6 +32,8 @@ const (
 	YAML DataFmt = iota
 	TOML
 	JSON
+	HCL
+	DOTENV
 	UNKNOWN
 )
 
@@ -48,6 +54,16 @@ func Unmarshal(b []byte, f DataFmt) (interface{}, error) {
 		if err := json.Unmarshal(b, &d); err != nil {
 			return nil, err
 		}
+	case HCL:
+		if err := hcl.Unmarshal(b, &d); err != nil {
+			return nil, err
+		}
+	case DOTENV:
+		dd, err := unmarshalDotenv(b)
+		if err != nil {
+			return nil, err
+		}
+		d = dd
 	default:
 		return nil, errors.New("unsupported data format")
 	}
@@ -55,6 +71,13 @@ func Unmarshal(b []byte, f DataFmt) (interface{}, error) {
 	return d, nil
 }
 
+// IsArrayLikeMap reports whether m's keys are exactly "0".."len(m)-1",
+// meaning Marshal would (by default) convert it back into a slice.
+func IsArrayLikeMap(m map[string]interface{}) bool {
+	_, ok := willRestore(m)
+	return ok
+}
+
 // UnmarshalPtr YAML/JSON/TOML serialized data.
 func UnmarshalPtr(ptr interface{}, b []byte, f DataFmt) error {
 	switch f {
@@ -70,6 +93,22 @@ func UnmarshalPtr(ptr interface{}, b []byte, f DataFmt) error {
 		if err := json.Unmarshal(b, ptr); err != nil {
 			return err
 		}
+	case HCL:
+		if err := hcl.Unmarshal(b, ptr); err != nil {
+			return err
+		}
+	case DOTENV:
+		d, err := unmarshalDotenv(b)
+		if err != nil {
+			return err
+		}
+		jb, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(jb, ptr); err != nil {
+			return err
+		}
 	default:
 		return errors.New("unsupported data format")
 	}
@@ -77,10 +116,195 @@ func UnmarshalPtr(ptr interface{}, b []byte, f DataFmt) error {
 	return nil
 }
 
+// UnmarshalPtrCanonical decodes YAML/JSON/TOML/HCL/dotenv data into ptr like
+// UnmarshalPtr, but first canonicalizes every map key (lower-cased, with
+// "_" and "-" stripped) so a struct field such as MaxConns matches
+// max_conns, maxConns or MAX-CONNS alike. Where ptr's underlying type has a
+// struct field at that position, the key is rewritten to that field's `json`
+// tag (or name) rather than left in its stripped form - otherwise a tag like
+// `json:"max_conns"` would never match a key canonicalized down to
+// "maxconns", and the field would silently keep its zero value. Keys that
+// collide after canonicalization return an error instead of silently
+// overwriting one another.
+func UnmarshalPtrCanonical(ptr interface{}, b []byte, f DataFmt) error {
+	d, err := Unmarshal(b, f)
+	if err != nil {
+		return err
+	}
+
+	canon, err := canonicalizeKeys(d, reflect.TypeOf(ptr))
+	if err != nil {
+		return err
+	}
+
+	jb, err := json.Marshal(canon)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jb, ptr)
+}
+
+// canonicalizeKeys recursively rewrites the keys of v to their canonical
+// form, preferring the json tag/name of the matching field on t - the
+// struct type (or slice/pointer thereof) v is ultimately headed for - when
+// one exists. t may be nil, e.g. when v is destined for a plain
+// map[string]interface{} or no static type is known for it; the key's
+// canonicalKey form is then used as is.
+func canonicalizeKeys(v interface{}, t reflect.Type) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fields := jsonFields(t)
+
+		res := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			ck := canonicalKey(k)
+			name := ck
+			var childType reflect.Type
+			if fi, ok := fields[ck]; ok {
+				name = fi.name
+				childType = fi.typ
+			}
+
+			if _, exists := res[name]; exists {
+				return nil, fmt.Errorf("key %q collides with another key after canonicalization", k)
+			}
+
+			cv, err := canonicalizeKeys(val, childType)
+			if err != nil {
+				return nil, err
+			}
+			res[name] = cv
+		}
+		return res, nil
+	case []interface{}:
+		elemType := sliceElemType(t)
+
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			cv, err := canonicalizeKeys(val, elemType)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = cv
+		}
+		return res, nil
+	case []map[string]interface{}:
+		elemType := sliceElemType(t)
+
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			cv, err := canonicalizeKeys(val, elemType)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = cv
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+func canonicalKey(k string) string {
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+	return k
+}
+
+// fieldInfo is what jsonFields resolves a canonicalized key to: the actual
+// name json.Unmarshal will match, and the field's type for recursing into
+// nested maps/slices.
+type fieldInfo struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields maps every exported field of t's underlying struct (t may be a
+// struct, or a pointer to one) to the name json.Unmarshal will match it by -
+// its `json` tag name if it has one, its field name otherwise - keyed by
+// that name's canonical form. It returns nil if t isn't (a pointer to) a
+// struct, e.g. when the target is a map or no static type is known.
+func jsonFields(t reflect.Type) map[string]fieldInfo {
+	t = underlyingStruct(t)
+	if t == nil {
+		return nil
+	}
+
+	fields := make(map[string]fieldInfo, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[canonicalKey(name)] = fieldInfo{name: name, typ: f.Type}
+	}
+	return fields
+}
+
+// underlyingStruct dereferences t down to its struct type, or returns nil if
+// t is nil or never resolves to one.
+func underlyingStruct(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// sliceElemType returns the element type of t once dereferenced down to a
+// slice or array, or nil if t never resolves to one.
+func sliceElemType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return nil
+	}
+	return t.Elem()
+}
+
 // Marshal YAML/JSON/TOML serialized data.
 func Marshal(d interface{}, f DataFmt) ([]byte, error) {
-    // restore array from map if its keys are 0, 1, 2...
-    res := restoreArrayMapValue(d)
+	return MarshalWithOptions(d, f, MarshalOptions{RestoreArrays: true})
+}
+
+// MarshalOptions controls array-restoration behavior for MarshalWithOptions.
+type MarshalOptions struct {
+	// RestoreArrays converts maps whose keys are "0".."n-1" back into
+	// slices, matching Marshal's historical behavior. iodatafmt cannot tell
+	// such a map apart from legitimate data whose string keys genuinely
+	// happen to be numeric - Unmarshal never synthesizes one itself, it
+	// only ever sees what the source document already contained - so the
+	// only way to protect that kind of data from being flattened into a
+	// slice is to set RestoreArrays to false.
+	//
+	// Unmarshal does not tag the maps it returns, so RestoreArrays applies
+	// to every numeric-keyed map alike; there is no way to restore only
+	// some of them.
+	RestoreArrays bool
+}
+
+// MarshalWithOptions is Marshal with finer control over array restoration.
+func MarshalWithOptions(d interface{}, f DataFmt, opts MarshalOptions) ([]byte, error) {
+	res := d
+	if opts.RestoreArrays {
+		res = restoreArrayMapValue(d)
+	}
 
 	switch f {
 	case YAML:
@@ -101,6 +325,10 @@ func Marshal(d interface{}, f DataFmt) ([]byte, error) {
 			return nil, err
 		}
 		return b, nil
+	case HCL:
+		return marshalHCL(res)
+	case DOTENV:
+		return marshalDotenv(res)
 	default:
 		return nil, errors.New("unsupported data format")
 	}
@@ -115,6 +343,10 @@ func Format(s string) (DataFmt, error) {
 		return TOML, nil
 	case "JSON":
 		return JSON, nil
+	case "HCL":
+		return HCL, nil
+	case "DOTENV", "ENV":
+		return DOTENV, nil
 	default:
 		return UNKNOWN, errors.New("unsupported data format")
 	}
@@ -133,24 +365,192 @@ func FileFormat(fn string) (DataFmt, error) {
 		return TOML, nil
 	case ".tml":
 		return TOML, nil
+	case ".hcl", ".tf":
+		return HCL, nil
+	case ".env":
+		return DOTENV, nil
 	default:
 		return UNKNOWN, errors.New("unsupported data format")
 	}
 }
 
+// Encoder writes serialized data to an output stream.
+type Encoder struct {
+	w       io.Writer
+	f       DataFmt
+	encoded bool
+}
+
+// NewEncoder returns an Encoder that writes to w in format f.
+func NewEncoder(w io.Writer, f DataFmt) *Encoder {
+	return &Encoder{w: w, f: f}
+}
+
+// Encode marshals v and writes it to the stream. For YAML, the "---"
+// document separator is written before every document after the first,
+// so repeated calls produce a valid multi-document stream.
+func (e *Encoder) Encode(v interface{}) error {
+	b, err := Marshal(v, e.f)
+	if err != nil {
+		return err
+	}
+
+	if e.f == YAML && e.encoded {
+		if _, err := e.w.Write([]byte("---\n")); err != nil {
+			return err
+		}
+	}
+	e.encoded = true
+
+	_, err = e.w.Write(b)
+	return err
+}
+
+// Decoder reads serialized data from an input stream.
+type Decoder struct {
+	r   io.Reader
+	f   DataFmt
+	dec *json.Decoder
+
+	// yamlDocs holds the "---"-separated documents of a YAML stream, read
+	// and split on the first Decode/More call; yamlIdx tracks which one
+	// Decode returns next.
+	yamlDocs [][]byte
+	yamlIdx  int
+	yamlRead bool
+
+	// read marks that the single TOML/HCL/dotenv document has already been
+	// consumed, so a second Decode call returns io.EOF instead of reading
+	// the now-empty r again and happily unmarshaling nothing.
+	read bool
+}
+
+// NewDecoder returns a Decoder that reads from r in format f.
+func NewDecoder(r io.Reader, f DataFmt) *Decoder {
+	d := &Decoder{r: r, f: f}
+	if f == JSON {
+		d.dec = json.NewDecoder(r)
+	}
+	return d
+}
+
+// Decode reads the next value from the stream into v. JSON and YAML both
+// support decoding multiple values from one stream - JSON by reading
+// consecutive values, YAML by splitting on "---" document separators, the
+// same ones Encoder.Encode writes between documents. TOML/HCL/dotenv
+// Decoders consume the whole reader on the first call, since round-tripping
+// them doesn't define a multi-document separator; a second Decode call on
+// one of those returns io.EOF, just like a YAML or JSON Decoder that has run
+// out of documents.
+func (d *Decoder) Decode(v interface{}) error {
+	if d.dec != nil {
+		return d.dec.Decode(v)
+	}
+
+	if d.f == YAML {
+		if err := d.readYAMLDocs(); err != nil {
+			return err
+		}
+		if d.yamlIdx >= len(d.yamlDocs) {
+			return io.EOF
+		}
+		doc := d.yamlDocs[d.yamlIdx]
+		d.yamlIdx++
+		return UnmarshalPtr(v, doc, YAML)
+	}
+
+	if d.read {
+		return io.EOF
+	}
+
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	d.read = true
+
+	return UnmarshalPtr(v, b, d.f)
+}
+
+// More reports whether there is another value to Decode. For JSON this
+// checks the underlying json.Decoder; for YAML it checks whether another
+// "---"-separated document remains; for TOML, HCL and dotenv it reports true
+// until the stream's single document has been consumed by Decode, then
+// false - so the standard `for dec.More() { dec.Decode(&v) }` idiom decodes
+// that one document instead of silently looping zero times.
+func (d *Decoder) More() bool {
+	if d.dec != nil {
+		return d.dec.More()
+	}
+	if d.f == YAML {
+		if err := d.readYAMLDocs(); err != nil {
+			return false
+		}
+		return d.yamlIdx < len(d.yamlDocs)
+	}
+	return !d.read
+}
+
+// readYAMLDocs reads the whole stream once and splits it into the
+// individual documents separated by a "---" line, skipping empty ones
+// (e.g. a leading separator or trailing blank document).
+func (d *Decoder) readYAMLDocs() error {
+	if d.yamlRead {
+		return nil
+	}
+
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range splitYAMLDocuments(b) {
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		d.yamlDocs = append(d.yamlDocs, doc)
+	}
+	d.yamlRead = true
+	return nil
+}
+
+// splitYAMLDocuments splits b on lines that are exactly "---" (ignoring a
+// trailing "\r"), the YAML document separator Encoder.Encode writes between
+// documents. The match is not anchored just to TrimSpace-equality: a "---"
+// line must start in column 0, the way a real document separator always
+// does, because a block scalar's content is indented relative to its key
+// and an indented "---" inside one (e.g. a literal horizontal-rule string)
+// must not be mistaken for a separator.
+func splitYAMLDocuments(b []byte) [][]byte {
+	lines := bytes.Split(b, []byte("\n"))
+
+	var docs [][]byte
+	var cur [][]byte
+	for _, line := range lines {
+		if bytes.Equal(bytes.TrimRight(line, "\r"), []byte("---")) {
+			docs = append(docs, bytes.Join(cur, []byte("\n")))
+			cur = nil
+			continue
+		}
+		cur = append(cur, line)
+	}
+	return append(docs, bytes.Join(cur, []byte("\n")))
+}
+
 // Load a file with serialized data.
 func Load(fn string, f DataFmt) (interface{}, error) {
 	if _, err := os.Stat(fn); os.IsNotExist(err) {
 		return nil, errors.New("file doesn't exist")
 	}
 
-	b, err := ioutil.ReadFile(fn)
+	file, err := os.Open(fn)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	d, err := Unmarshal(b, f)
-	if err != nil {
+	var d interface{}
+	if err := NewDecoder(file, f).Decode(&d); err != nil {
 		return nil, err
 	}
 
@@ -177,22 +577,49 @@ func LoadPtr(ptr interface{}, fn string, f DataFmt) error {
 
 // Write a file with serialized data.
 func Write(fn string, d map[string]interface{}, f DataFmt) error {
-	b, err := Marshal(d, f)
+	w, err := os.Create(fn)
 	if err != nil {
 		return err
 	}
+	defer w.Close()
 
-	w, err := os.Create(fn)
+	return NewEncoder(w, f).Encode(d)
+}
+
+// Convert re-serializes src from format "from" into format "to".
+func Convert(src []byte, from, to DataFmt) ([]byte, error) {
+	d, err := Unmarshal(src, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return Marshal(d, to)
+}
+
+// ConvertFile converts the file at srcPath into dstPath, auto-detecting
+// both formats from their file extensions via FileFormat.
+func ConvertFile(srcPath, dstPath string) error {
+	from, err := FileFormat(srcPath)
 	if err != nil {
 		return err
 	}
 
-	if _, err = w.Write(b); err != nil {
+	to, err := FileFormat(dstPath)
+	if err != nil {
 		return err
 	}
 
-	w.Close()
-	return nil
+	b, err := ioutil.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+
+	out, err := Convert(b, from, to)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(dstPath, out, 0644)
 }
 
 // Print serialized data.
@@ -217,63 +644,245 @@ func Sprint(d interface{}, f DataFmt) (string, error) {
 }
 
 func willRestore(in map[string]interface{}) ([]string, bool) {
-    // zero length map shouldn't be restored
-    if (len(in) == 0) {
-        return nil, false
-    }
-
-    // Convert map to slice of keys.
-    keys := []string{}
-    for key, _ := range in {
-        keys = append(keys, key)
-    }
-
-    sort.Strings(keys)
+	// zero length map shouldn't be restored
+	if len(in) == 0 {
+		return nil, false
+	}
 
-    for i, _ := range keys {
-        if (keys[i] != strconv.Itoa(i)) {
-            return nil, false
-        }
-    }
+	// Collect the keys "0".."len(in)-1" in numeric order; any key missing
+	// from that range means in isn't array-like.
+	keys := make([]string, len(in))
+	for i := range keys {
+		key := strconv.Itoa(i)
+		if _, ok := in[key]; !ok {
+			return nil, false
+		}
+		keys[i] = key
+	}
 
-    return keys, true
+	return keys, true
 }
 
 func restoreArrayInterfaceArray(in []interface{}) interface{} {
-    res := make([]interface{}, len(in))
-    for i, v := range in {
-        res[i] = restoreArrayMapValue(v)
-    }
-    return res
+	res := make([]interface{}, len(in))
+	for i, v := range in {
+		res[i] = restoreArrayMapValue(v)
+	}
+	return res
 }
 
 func restoreArrayInterfaceMap(in map[string]interface{}) interface{} {
-    res := make(map[string]interface{})
-    for k, v := range in {
-        res[fmt.Sprintf("%v", k)] = restoreArrayMapValue(v)
-    }
-
-    keys, b := willRestore(res)
-    if (b) {
-        var array []interface{}
-        for _, key := range keys {
-            array = append(array, res[key])
-        }
-        return array
-    }
-    return res
+	res := make(map[string]interface{})
+	for k, v := range in {
+		res[fmt.Sprintf("%v", k)] = restoreArrayMapValue(v)
+	}
+
+	keys, b := willRestore(res)
+	if b {
+		var array []interface{}
+		for _, key := range keys {
+			array = append(array, res[key])
+		}
+		return array
+	}
+	return res
 }
 
 func restoreArrayMapValue(v interface{}) interface{} {
-    switch v := v.(type) {
-    case []interface{}:
-        return restoreArrayInterfaceArray(v)
-    case map[string]interface{}:
-        return restoreArrayInterfaceMap(v)
-    case string:
-        return v
-    default:
-        //return fmt.Sprintf("%v", v)
-        return v
-    }
+	switch v := v.(type) {
+	case []interface{}:
+		return restoreArrayInterfaceArray(v)
+	case map[string]interface{}:
+		return restoreArrayInterfaceMap(v)
+	case string:
+		return v
+	default:
+		//return fmt.Sprintf("%v", v)
+		return v
+	}
+}
+
+// marshalHCL writes d as HCL. A nested map is written as a `key = { ... }`
+// object assignment and a slice of maps as repeated `key { ... }` blocks,
+// which Unmarshal collapses back into one slice. hashicorp/hcl (v1) decodes
+// both forms below the root into a []map[string]interface{}, so a nested map
+// does not come back as a plain map[string]interface{}. Only maps, slices
+// of scalars or of maps, strings, bools, ints, int64s and float64s are
+// written; anything else is rejected.
+func marshalHCL(d interface{}) ([]byte, error) {
+	m, ok := d.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("iodatafmt: HCL root must be a map")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := writeHCLBody(buf, m, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHCLBody(buf *bytes.Buffer, m map[string]interface{}, indent int) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := writeHCLAttr(buf, k, m[k], indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHCLAttr(buf *bytes.Buffer, key string, v interface{}, indent int) error {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fmt.Fprintf(buf, "%s%s = {\n", pad, key)
+		if err := writeHCLBody(buf, v, indent+1); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s}\n", pad)
+		return nil
+	case []interface{}:
+		if hclBlockElems(v) {
+			for _, e := range v {
+				fmt.Fprintf(buf, "%s%s {\n", pad, key)
+				if err := writeHCLBody(buf, e.(map[string]interface{}), indent+1); err != nil {
+					return err
+				}
+				fmt.Fprintf(buf, "%s}\n", pad)
+			}
+			return nil
+		}
+
+		lits := make([]string, len(v))
+		for i, e := range v {
+			lit, err := hclLiteral(e)
+			if err != nil {
+				return err
+			}
+			lits[i] = lit
+		}
+		fmt.Fprintf(buf, "%s%s = [%s]\n", pad, key, strings.Join(lits, ", "))
+		return nil
+	default:
+		lit, err := hclLiteral(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s%s = %s\n", pad, key, lit)
+		return nil
+	}
+}
+
+// hclBlockElems reports whether every element of v is a map, so it should be
+// written as repeated `key { ... }` blocks instead of a `key = [...]` array
+// literal. An empty slice is ambiguous - Unmarshal never produces one - so
+// it's written as an empty array literal.
+func hclBlockElems(v []interface{}) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for _, e := range v {
+		if _, ok := e.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func hclLiteral(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, float64:
+		return fmt.Sprintf("%v", v), nil
+	default:
+		return "", fmt.Errorf("iodatafmt: HCL marshaling doesn't support value of type %T", v)
+	}
+}
+
+// unmarshalDotenv parses KEY=VALUE dotenv lines into a flat
+// map[string]interface{}, ignoring blank lines and "#" comments.
+func unmarshalDotenv(b []byte) (map[string]interface{}, error) {
+	d := make(map[string]interface{})
+
+	lines := strings.Split(string(b), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid dotenv line: %q", line)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		val := strings.TrimSpace(parts[1])
+		val = strings.Trim(val, `"'`)
+		d[key] = val
+	}
+
+	return d, nil
+}
+
+// marshalDotenv flattens d into KEY=VALUE lines, joining nested map keys
+// with "_" and upper-casing the result (e.g. {"db": {"host": "x"}} becomes
+// DB_HOST="x"). Non-scalar leaves (maps handled via flattening, arrays are
+// not) are rejected with a descriptive error.
+func marshalDotenv(d interface{}) ([]byte, error) {
+	m, ok := d.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("dotenv root must be a map")
+	}
+
+	pairs := make(map[string]string)
+	if err := flattenDotenv(m, "", pairs); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := new(bytes.Buffer)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s=%q\n", k, pairs[k])
+	}
+	return b.Bytes(), nil
+}
+
+func flattenDotenv(m map[string]interface{}, prefix string, out map[string]string) error {
+	for k, v := range m {
+		key := strings.ToUpper(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if err := flattenDotenv(v, key, out); err != nil {
+				return err
+			}
+		case string, bool, int, int64, float64, nil:
+			if _, exists := out[key]; exists {
+				return fmt.Errorf("dotenv: key %q collides with another key after flattening", key)
+			}
+			out[key] = fmt.Sprintf("%v", v)
+		default:
+			return fmt.Errorf("dotenv: key %q has a non-scalar value that can't be flattened", k)
+		}
+	}
+	return nil
 }
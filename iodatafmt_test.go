@@ -0,0 +1,179 @@
+package iodatafmt
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMarshalHCLRoundTrip(t *testing.T) {
+	d := map[string]interface{}{
+		"name": "demo",
+		"tags": []interface{}{"a", "b"},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": 5432,
+		},
+		"server": []interface{}{
+			map[string]interface{}{"addr": "10.0.0.1"},
+			map[string]interface{}{"addr": "10.0.0.2"},
+		},
+	}
+
+	b, err := Marshal(d, HCL)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	got, err := Unmarshal(b, HCL)
+	if err != nil {
+		t.Fatalf("Unmarshal(%s): %v", b, err)
+	}
+
+	m, ok := got.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Unmarshal returned %T, want map[string]interface{}", got)
+	}
+	if m["name"] != "demo" {
+		t.Errorf("name = %v, want demo", m["name"])
+	}
+	servers, ok := m["server"].([]map[string]interface{})
+	if !ok || len(servers) != 2 {
+		t.Errorf("server = %#v, want two blocks", m["server"])
+	}
+}
+
+func TestMarshalHCLRejectsNonMapRoot(t *testing.T) {
+	if _, err := Marshal([]interface{}{"a"}, HCL); err == nil {
+		t.Fatal("expected error marshaling a non-map root to HCL")
+	}
+}
+
+func TestMarshalWithOptionsRestoreArraysFalse(t *testing.T) {
+	d := map[string]interface{}{
+		"0": "zeroth",
+		"1": "first",
+	}
+
+	if !IsArrayLikeMap(d) {
+		t.Fatal("expected d to be detected as array-like")
+	}
+
+	restored, err := MarshalWithOptions(d, JSON, MarshalOptions{RestoreArrays: true})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions(RestoreArrays: true): %v", err)
+	}
+	if strings.Contains(string(restored), `"0"`) {
+		t.Errorf("RestoreArrays: true still has map keys: %s", restored)
+	}
+
+	kept, err := MarshalWithOptions(d, JSON, MarshalOptions{RestoreArrays: false})
+	if err != nil {
+		t.Fatalf("MarshalWithOptions(RestoreArrays: false): %v", err)
+	}
+	if !strings.Contains(string(kept), `"0"`) {
+		t.Errorf("RestoreArrays: false should keep the original map, got %s", kept)
+	}
+}
+
+func TestSplitYAMLDocumentsIgnoresIndentedSeparator(t *testing.T) {
+	b := []byte("a: |\n  ---\n  still the value\nb: 2\n")
+
+	docs := splitYAMLDocuments(b)
+	if len(docs) != 1 {
+		t.Fatalf("got %d documents, want 1: %q", len(docs), docs)
+	}
+	if string(docs[0]) != string(b) {
+		t.Errorf("document = %q, want it unchanged: %q", docs[0], b)
+	}
+}
+
+func TestSplitYAMLDocumentsSplitsOnColumnZeroSeparator(t *testing.T) {
+	b := []byte("a: 1\n---\nb: 2\n")
+
+	docs := splitYAMLDocuments(b)
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want 2: %q", len(docs), docs)
+	}
+}
+
+func TestDecoderEOFOnRepeatedDecodeForSingleDocFormats(t *testing.T) {
+	r := strings.NewReader(`a = "b"`)
+	dec := NewDecoder(r, TOML)
+
+	var first map[string]interface{}
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("first Decode: %v", err)
+	}
+
+	var second map[string]interface{}
+	if err := dec.Decode(&second); err != io.EOF {
+		t.Errorf("second Decode = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderMoreForSingleDocFormats(t *testing.T) {
+	r := strings.NewReader(`a = "b"`)
+	dec := NewDecoder(r, TOML)
+
+	if !dec.More() {
+		t.Fatal("More() = false before the single document has been read, want true")
+	}
+
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if dec.More() {
+		t.Fatal("More() = true after the single document has been read, want false")
+	}
+}
+
+func TestUnmarshalPtrCanonicalMatchesUnderscoredJSONTag(t *testing.T) {
+	type Config struct {
+		MaxConns int `json:"max_conns"`
+	}
+
+	var cfg Config
+	if err := UnmarshalPtrCanonical(&cfg, []byte(`{"maxConns": 5}`), JSON); err != nil {
+		t.Fatalf("UnmarshalPtrCanonical: %v", err)
+	}
+	if cfg.MaxConns != 5 {
+		t.Errorf("MaxConns = %d, want 5", cfg.MaxConns)
+	}
+}
+
+func TestUnmarshalPtrCanonicalNestedStruct(t *testing.T) {
+	type DB struct {
+		MaxConns int `json:"max_conns"`
+	}
+	type Config struct {
+		DB DB `json:"db"`
+	}
+
+	var cfg Config
+	if err := UnmarshalPtrCanonical(&cfg, []byte(`{"DB": {"MAX-CONNS": 7}}`), JSON); err != nil {
+		t.Fatalf("UnmarshalPtrCanonical: %v", err)
+	}
+	if cfg.DB.MaxConns != 7 {
+		t.Errorf("DB.MaxConns = %d, want 7", cfg.DB.MaxConns)
+	}
+}
+
+func TestMarshalDotenvCollision(t *testing.T) {
+	d := map[string]interface{}{
+		"DB_HOST": "top-level",
+		"db": map[string]interface{}{
+			"host": "nested",
+		},
+	}
+
+	_, err := Marshal(d, DOTENV)
+	if err == nil {
+		t.Fatal("expected an error for keys that collide after flattening")
+	}
+	if !strings.Contains(err.Error(), "DB_HOST") {
+		t.Errorf("error = %q, want it to name the colliding key DB_HOST", err)
+	}
+}